@@ -19,14 +19,17 @@ package jenkins
 import (
 	"bytes"
 	"fmt"
+	"math/rand"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/jenkins/state"
 	"k8s.io/test-infra/prow/kube"
 	"k8s.io/test-infra/prow/pjutil"
 	reportlib "k8s.io/test-infra/prow/report"
@@ -34,18 +37,56 @@ import (
 
 const (
 	testInfra = "https://github.com/kubernetes/test-infra/issues"
+
+	// defaultMaster is the Jenkins master used for ProwJobs that don't set
+	// Spec.JenkinsMaster.
+	defaultMaster = "default"
+
+	// defaultHealthCheckInterval is how often master health checks run when
+	// JenkinsOperator.HealthCheckInterval is unset.
+	defaultHealthCheckInterval = 30 * time.Second
+
+	// defaultGCInterval is how often GC runs when JenkinsOperator.GCInterval is unset.
+	defaultGCInterval = 10 * time.Minute
+	// defaultTTLSecondsAfterFinished is the TTL applied to a completed ProwJob
+	// when neither the job nor JenkinsOperator.DefaultTTLSecondsAfterFinished
+	// specify one.
+	defaultTTLSecondsAfterFinished = 24 * 60 * 60
+
+	// defaultRetryBase and defaultRetryCap are used when JenkinsOperator.RetryPolicy
+	// leaves Base/MaxBackoff unset.
+	defaultRetryBase = 30 * time.Second
+	defaultRetryCap  = 6 * time.Hour
+)
+
+var (
+	prowJobsGCed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "jenkins_operator_prowjobs_gced",
+		Help: "Number of Jenkins-agent ProwJobs garbage collected after exceeding their TTL.",
+	})
+	prowJobGCErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "jenkins_operator_prowjob_gc_errors",
+		Help: "Number of errors encountered while garbage collecting ProwJobs.",
+	})
 )
 
+func init() {
+	prometheus.MustRegister(prowJobsGCed)
+	prometheus.MustRegister(prowJobGCErrors)
+}
+
 type kubeClient interface {
 	CreateProwJob(kube.ProwJob) (kube.ProwJob, error)
 	ListProwJobs(string) ([]kube.ProwJob, error)
 	ReplaceProwJob(string, kube.ProwJob) (kube.ProwJob, error)
+	DeleteProwJob(string) error
 }
 
 type jenkinsClient interface {
 	Build(*kube.ProwJob) error
 	ListBuilds(jobs []string) (map[string]JenkinsBuild, error)
 	Abort(job string, build *JenkinsBuild) error
+	Healthy() error
 }
 
 type githubClient interface {
@@ -66,8 +107,10 @@ type syncFn func(kube.ProwJob, chan<- kube.ProwJob, map[string]JenkinsBuild) err
 
 // Controller manages ProwJobs.
 type Controller struct {
-	kc  kubeClient
-	jc  jenkinsClient
+	kc kubeClient
+	// jc is keyed by Jenkins master name (kube.ProwJob.Spec.JenkinsMaster),
+	// with defaultMaster used for ProwJobs that don't set it.
+	jc  map[string]jenkinsClient
 	ghc githubClient
 	log *logrus.Entry
 	ca  configAgent
@@ -78,31 +121,106 @@ type Controller struct {
 	// pendingJobs is a short-lived cache that helps in limiting
 	// the maximum concurrency of jobs.
 	pendingJobs map[string]int
+	// pendingJobsByMaster mirrors pendingJobs, partitioned per Jenkins
+	// master, so canExecuteConcurrently can also enforce
+	// JenkinsOperator.MaxConcurrencyPerMaster.
+	pendingJobsByMaster map[string]map[string]int
+
+	healthLock sync.RWMutex
+	// unhealthyMasters holds the set of Jenkins masters the most recent
+	// HealthCheck could not reach. New builds are not scheduled against
+	// them until they recover.
+	unhealthyMasters map[string]bool
 
 	pjLock sync.RWMutex
 	// shared across the controller and a goroutine that gathers metrics.
 	pjs []kube.ProwJob
 }
 
-// NewController creates a new Controller from the provided clients.
-func NewController(kc *kube.Client, jc *Client, ghc *github.Client, logger *logrus.Entry, ca *config.Agent, selector string) *Controller {
+// NewController creates a new Controller from the provided clients. jc maps
+// Jenkins master name to the client for that master; it must contain an
+// entry for defaultMaster ("default"), which is used for ProwJobs that
+// don't set Spec.JenkinsMaster.
+func NewController(kc *kube.Client, jc map[string]*Client, ghc *github.Client, logger *logrus.Entry, ca *config.Agent, selector string) *Controller {
 	if logger == nil {
 		logger = logrus.NewEntry(logrus.StandardLogger())
 	}
+	jenkinsClients := make(map[string]jenkinsClient, len(jc))
+	for master, client := range jc {
+		jenkinsClients[master] = client
+	}
 	return &Controller{
-		kc:          kc,
-		jc:          jc,
-		ghc:         ghc,
-		log:         logger,
-		ca:          ca,
-		selector:    selector,
-		pendingJobs: make(map[string]int),
+		kc:                  kc,
+		jc:                  jenkinsClients,
+		ghc:                 ghc,
+		log:                 logger,
+		ca:                  ca,
+		selector:            selector,
+		pendingJobs:         make(map[string]int),
+		pendingJobsByMaster: make(map[string]map[string]int),
 	}
 }
 
+// masterFor returns the Jenkins master a ProwJob should be scheduled
+// against, defaulting to defaultMaster when Spec.JenkinsMaster is unset.
+func masterFor(pj *kube.ProwJob) string {
+	if pj.Spec.JenkinsMaster == "" {
+		return defaultMaster
+	}
+	return pj.Spec.JenkinsMaster
+}
+
+// jenkinsClientFor returns the jenkinsClient for the master a ProwJob is
+// scheduled against.
+func (c *Controller) jenkinsClientFor(pj *kube.ProwJob) (jenkinsClient, error) {
+	master := masterFor(pj)
+	jc, ok := c.jc[master]
+	if !ok {
+		return nil, fmt.Errorf("no Jenkins client configured for master %q", master)
+	}
+	return jc, nil
+}
+
+// masterHealthy reports whether the most recent HealthCheck could reach the
+// given Jenkins master. Masters with no recorded check yet are assumed
+// healthy.
+func (c *Controller) masterHealthy(master string) bool {
+	c.healthLock.RLock()
+	defer c.healthLock.RUnlock()
+	return !c.unhealthyMasters[master]
+}
+
+// HealthCheck pings every configured Jenkins master and records which ones
+// are currently unreachable, so that Sync avoids scheduling new builds
+// against them while still reconciling builds already running there. It is
+// meant to be run periodically alongside Sync, SyncMetrics and GC.
+func (c *Controller) HealthCheck() {
+	unhealthy := make(map[string]bool)
+	for master, jc := range c.jc {
+		if err := jc.Healthy(); err != nil {
+			c.log.WithError(err).WithField("master", master).Warn("Jenkins master health check failed.")
+			unhealthy[master] = true
+		}
+	}
+	c.healthLock.Lock()
+	c.unhealthyMasters = unhealthy
+	c.healthLock.Unlock()
+}
+
+// HealthCheckInterval returns how often HealthCheck should be run,
+// defaulting to defaultHealthCheckInterval when
+// JenkinsOperator.HealthCheckInterval is unset.
+func (c *Controller) HealthCheckInterval() time.Duration {
+	if d := c.ca.Config().JenkinsOperator.HealthCheckInterval; d > 0 {
+		return d
+	}
+	return defaultHealthCheckInterval
+}
+
 // canExecuteConcurrently checks whether the provided ProwJob can
-// be executed concurrently.
-func (c *Controller) canExecuteConcurrently(pj *kube.ProwJob) bool {
+// be executed concurrently, against both the global MaxConcurrency and
+// MaxConcurrencyPerMaster for the master it is scheduled against.
+func (c *Controller) canExecuteConcurrently(pj *kube.ProwJob, master string) bool {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -117,8 +235,19 @@ func (c *Controller) canExecuteConcurrently(pj *kube.ProwJob) bool {
 		}
 	}
 
+	if max := c.ca.Config().JenkinsOperator.MaxConcurrencyPerMaster; max > 0 {
+		var runningOnMaster int
+		for _, num := range c.pendingJobsByMaster[master] {
+			runningOnMaster += num
+		}
+		if runningOnMaster >= max {
+			c.log.WithFields(pjutil.ProwJobFields(pj)).Debugf("Not starting another job on master %s, already %d running.", master, runningOnMaster)
+			return false
+		}
+	}
+
 	if pj.Spec.MaxConcurrency == 0 {
-		c.pendingJobs[pj.Spec.Job]++
+		c.incrementNumPendingJobsLocked(pj.Spec.Job, master)
 		return true
 	}
 
@@ -127,16 +256,26 @@ func (c *Controller) canExecuteConcurrently(pj *kube.ProwJob) bool {
 		c.log.WithFields(pjutil.ProwJobFields(pj)).Debugf("Not starting another instance of %s, already %d running.", pj.Spec.Job, numPending)
 		return false
 	}
-	c.pendingJobs[pj.Spec.Job]++
+	c.incrementNumPendingJobsLocked(pj.Spec.Job, master)
 	return true
 }
 
-// incrementNumPendingJobs increments the amount of
-// pending ProwJobs for the given job identifier
-func (c *Controller) incrementNumPendingJobs(job string) {
+// incrementNumPendingJobs increments the amount of pending ProwJobs for the
+// given job identifier, both overall and for the given Jenkins master.
+func (c *Controller) incrementNumPendingJobs(job, master string) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	c.incrementNumPendingJobsLocked(job, master)
+}
+
+// incrementNumPendingJobsLocked is incrementNumPendingJobs for callers that
+// already hold c.lock.
+func (c *Controller) incrementNumPendingJobsLocked(job, master string) {
 	c.pendingJobs[job]++
+	if c.pendingJobsByMaster[master] == nil {
+		c.pendingJobsByMaster[master] = make(map[string]int)
+	}
+	c.pendingJobsByMaster[master][job]++
 }
 
 // Sync does one sync iteration.
@@ -154,12 +293,37 @@ func (c *Controller) Sync() error {
 		}
 	}
 	pjs = jenkinsJobs
-	jbs, err := c.jc.ListBuilds(getJenkinsJobs(pjs))
-	if err != nil {
-		return fmt.Errorf("error listing jenkins builds: %v", err)
-	}
 
+	// Fan out ListBuilds per Jenkins master: each master only knows about
+	// its own builds, so jobs must be grouped before asking for their
+	// statuses.
+	pjsByMaster := make(map[string][]kube.ProwJob)
+	for _, pj := range pjs {
+		master := masterFor(&pj)
+		pjsByMaster[master] = append(pjsByMaster[master], pj)
+	}
+	jbs := make(map[string]JenkinsBuild)
 	var syncErrs []error
+	for master, mpjs := range pjsByMaster {
+		jc, ok := c.jc[master]
+		if !ok {
+			c.log.WithField("master", master).Warn("No Jenkins client configured for this master; skipping its jobs.")
+			continue
+		}
+		masterBuilds, err := jc.ListBuilds(getJenkinsJobs(mpjs))
+		if err != nil {
+			// Don't let one unreachable master stall reconciliation for the
+			// others; its jobs are simply skipped for this Sync and picked
+			// up again next tick.
+			c.log.WithError(err).WithField("master", master).Warn("Error listing Jenkins builds for master; skipping its jobs.")
+			syncErrs = append(syncErrs, fmt.Errorf("error listing jenkins builds for master %q: %v", master, err))
+			continue
+		}
+		for name, b := range masterBuilds {
+			jbs[name] = b
+		}
+	}
+
 	if err := c.terminateDupes(pjs, jbs); err != nil {
 		syncErrs = append(syncErrs, err)
 	}
@@ -176,6 +340,7 @@ func (c *Controller) Sync() error {
 	// Reinstantiate on every resync of the controller instead of trying
 	// to keep this in sync with the state of the world.
 	c.pendingJobs = make(map[string]int)
+	c.pendingJobsByMaster = make(map[string]map[string]int)
 	// Sync pending jobs first so we can determine what is the maximum
 	// number of new jobs we can trigger when syncing the non-pendings.
 	maxSyncRoutines := c.ca.Config().JenkinsOperator.MaxGoroutines
@@ -210,6 +375,62 @@ func (c *Controller) SyncMetrics() {
 	kube.GatherProwJobMetrics(c.pjs)
 }
 
+// GC removes completed Jenkins-agent ProwJobs whose TTL has expired. It is
+// meant to be run periodically alongside Sync and SyncMetrics, e.g. from a
+// ticker in main(), so that long-lived clusters don't accumulate unbounded
+// ProwJob CRs in etcd.
+func (c *Controller) GC() error {
+	pjs, err := c.kc.ListProwJobs(c.selector)
+	if err != nil {
+		return fmt.Errorf("error listing prow jobs: %v", err)
+	}
+
+	defaultTTL := time.Duration(defaultTTLSecondsAfterFinished) * time.Second
+	if d := c.ca.Config().JenkinsOperator.DefaultTTLSecondsAfterFinished; d > 0 {
+		defaultTTL = time.Duration(d) * time.Second
+	}
+
+	var gcErrs []error
+	for _, pj := range pjs {
+		if pj.Spec.Agent != kube.JenkinsAgent {
+			continue
+		}
+		if !pj.Complete() {
+			continue
+		}
+
+		ttl := defaultTTL
+		if pj.Spec.TTLSecondsAfterFinished != nil {
+			ttl = time.Duration(*pj.Spec.TTLSecondsAfterFinished) * time.Second
+		}
+		if ttl <= 0 || time.Since(pj.Status.CompletionTime) < ttl {
+			continue
+		}
+
+		if err := c.kc.DeleteProwJob(pj.Metadata.Name); err != nil {
+			prowJobGCErrors.Inc()
+			gcErrs = append(gcErrs, fmt.Errorf("error deleting prowjob %s: %v", pj.Metadata.Name, err))
+			continue
+		}
+		prowJobsGCed.Inc()
+		c.log.WithFields(pjutil.ProwJobFields(&pj)).Info("Garbage collected expired ProwJob.")
+	}
+
+	if len(gcErrs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("errors garbage collecting prowjobs: %v", gcErrs)
+}
+
+// GCInterval returns how often GC should be run, defaulting to
+// defaultGCInterval when JenkinsOperator.GCInterval is unset.
+func (c *Controller) GCInterval() time.Duration {
+	if d := c.ca.Config().JenkinsOperator.GCInterval; d > 0 {
+		return d
+	}
+	return defaultGCInterval
+}
+
 // getJenkinsJobs returns all the Jenkins jobs for all active
 // prowjobs from the provided list. It handles deduplication.
 func getJenkinsJobs(pjs []kube.ProwJob) []string {
@@ -258,14 +479,21 @@ func (c *Controller) terminateDupes(pjs []kube.ProwJob, jbs map[string]JenkinsBu
 			}
 			// Otherwise, abort it.
 			if buildExists {
-				if err := c.jc.Abort(toCancel.Spec.Job, &build); err != nil {
+				jc, err := c.jenkinsClientFor(&toCancel)
+				if err != nil {
+					c.log.WithError(err).WithFields(pjutil.ProwJobFields(&toCancel)).Warn("Cannot cancel Jenkins build")
+				} else if err := jc.Abort(toCancel.Spec.Job, &build); err != nil {
 					c.log.WithError(err).WithFields(pjutil.ProwJobFields(&toCancel)).Warn("Cannot cancel Jenkins build")
 				}
 			}
 		}
+		next, err := state.New(toCancel.Status.State).Execute(state.Abort, state.Context{})
+		if err != nil {
+			return err
+		}
 		toCancel.Status.CompletionTime = time.Now()
 		prevState := toCancel.Status.State
-		toCancel.Status.State = kube.AbortedState
+		toCancel.Status.State = next.Name()
 		c.log.WithFields(pjutil.ProwJobFields(&toCancel)).
 			WithField("from", prevState).
 			WithField("to", toCancel.Status.State).Info("Transitioning states.")
@@ -305,52 +533,87 @@ func syncProwJobs(
 func (c *Controller) syncPendingJob(pj kube.ProwJob, reports chan<- kube.ProwJob, jbs map[string]JenkinsBuild) error {
 	prevState := pj.Status.State
 
+	if pj.Spec.Suspend != nil && *pj.Spec.Suspend {
+		return c.suspendPendingJob(pj, jbs, prevState)
+	}
+
+	// A previous build failed and we are backing off before retrying it.
+	// From kube's perspective this is still a Pending ProwJob (so it keeps
+	// being routed here by pjutil.PartitionPending); NextRetryTime is the
+	// only on-the-wire signal that we're in state.RetryingProwJobState.
+	if !pj.Status.NextRetryTime.IsZero() {
+		if time.Now().Before(pj.Status.NextRetryTime) {
+			c.incrementNumPendingJobs(pj.Spec.Job, masterFor(&pj))
+			return nil
+		}
+		return c.retryPendingJob(pj, reports, prevState)
+	}
+
 	jb, jbExists := jbs[pj.Metadata.Name]
-	if !jbExists {
+	next, err := state.New(pj.Status.State).Execute(state.SyncStatus, state.Context{
+		Build: state.Build{
+			Exists:   jbExists,
+			Enqueued: jbExists && jb.IsEnqueued(),
+			Running:  jbExists && jb.IsRunning(),
+			Success:  jbExists && jb.IsSuccess(),
+			Failure:  jbExists && jb.IsFailure(),
+			Aborted:  jbExists && jb.IsAborted(),
+		},
+		RetryCount:         pj.Status.RetryCount,
+		MaxRetry:           pj.Spec.MaxRetry,
+		RetryJenkinsAborts: c.ca.Config().JenkinsOperator.RetryPolicy.RetryJenkinsAborts,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch next.Name() {
+	case kube.ErrorState:
 		pj.Status.CompletionTime = time.Now()
 		pj.Status.State = kube.ErrorState
 		pj.Status.URL = testInfra
 		pj.Status.Description = "Error finding Jenkins job."
-	} else {
-		switch {
-		case jb.IsEnqueued():
-			// Still in queue.
-			c.incrementNumPendingJobs(pj.Spec.Job)
+
+	case kube.PendingState:
+		// Still enqueued or running.
+		c.incrementNumPendingJobs(pj.Spec.Job, masterFor(&pj))
+		if jb.IsEnqueued() {
+			return nil
+		}
+		if pj.Status.Description == "Jenkins job running." {
 			return nil
+		}
+		pj.Status.Description = "Jenkins job running."
 
-		case jb.IsRunning():
-			// Build still going.
-			c.incrementNumPendingJobs(pj.Spec.Job)
-			if pj.Status.Description == "Jenkins job running." {
-				return nil
+	case kube.SuccessState:
+		pj.Status.CompletionTime = time.Now()
+		pj.Status.State = kube.SuccessState
+		pj.Status.Description = "Jenkins job succeeded."
+		for _, nj := range pj.Spec.RunAfterSuccess {
+			child := pjutil.NewProwJob(nj, pj.Metadata.Labels)
+			if !c.RunAfterSuccessCanRun(&pj, &child, c.ca, c.ghc) {
+				continue
 			}
-			pj.Status.Description = "Jenkins job running."
-
-		case jb.IsSuccess():
-			// Build is complete.
-			pj.Status.CompletionTime = time.Now()
-			pj.Status.State = kube.SuccessState
-			pj.Status.Description = "Jenkins job succeeded."
-			for _, nj := range pj.Spec.RunAfterSuccess {
-				child := pjutil.NewProwJob(nj, pj.Metadata.Labels)
-				if !c.RunAfterSuccessCanRun(&pj, &child, c.ca, c.ghc) {
-					continue
-				}
-				if _, err := c.kc.CreateProwJob(pjutil.NewProwJob(nj, pj.Metadata.Labels)); err != nil {
-					return fmt.Errorf("error starting next prowjob: %v", err)
-				}
+			if _, err := c.kc.CreateProwJob(pjutil.NewProwJob(nj, pj.Metadata.Labels)); err != nil {
+				return fmt.Errorf("error starting next prowjob: %v", err)
 			}
+		}
 
-		case jb.IsFailure():
-			pj.Status.CompletionTime = time.Now()
-			pj.Status.State = kube.FailureState
-			pj.Status.Description = "Jenkins job failed."
+	case kube.FailureState:
+		pj.Status.CompletionTime = time.Now()
+		pj.Status.State = kube.FailureState
+		pj.Status.Description = "Jenkins job failed."
 
-		case jb.IsAborted():
-			pj.Status.CompletionTime = time.Now()
-			pj.Status.State = kube.AbortedState
-			pj.Status.Description = "Jenkins job aborted."
-		}
+	case kube.AbortedState:
+		pj.Status.CompletionTime = time.Now()
+		pj.Status.State = kube.AbortedState
+		pj.Status.Description = "Jenkins job aborted."
+
+	case state.RetryingProwJobState:
+		c.stageRetry(&pj)
+	}
+
+	if jbExists {
 		// Construct the status URL that will be used in reports.
 		pj.Status.PodName = fmt.Sprintf("%s-%d", pj.Spec.Job, jb.Number)
 		pj.Status.BuildID = strconv.Itoa(jb.Number)
@@ -367,10 +630,122 @@ func (c *Controller) syncPendingJob(pj kube.ProwJob, reports chan<- kube.ProwJob
 			WithField("from", prevState).
 			WithField("to", pj.Status.State).Info("Transitioning states.")
 	}
+	_, err = c.kc.ReplaceProwJob(pj.Metadata.Name, pj)
+	return err
+}
+
+// suspendPendingJob aborts the corresponding Jenkins build, if any, for a
+// ProwJob that was marked suspended while Pending, and transitions it to
+// SuspendedState. Suspended jobs are not re-added to pendingJobs, freeing up
+// their slot in the concurrency window for other jobs.
+func (c *Controller) suspendPendingJob(pj kube.ProwJob, jbs map[string]JenkinsBuild, prevState kube.ProwJobState) error {
+	if build, exists := jbs[pj.Metadata.Name]; exists {
+		if jc, err := c.jenkinsClientFor(&pj); err != nil {
+			c.log.WithError(err).WithFields(pjutil.ProwJobFields(&pj)).Warn("Cannot abort Jenkins build for suspended ProwJob")
+		} else if err := jc.Abort(pj.Spec.Job, &build); err != nil {
+			c.log.WithError(err).WithFields(pjutil.ProwJobFields(&pj)).Warn("Cannot abort Jenkins build for suspended ProwJob")
+		}
+	}
+	pj.Status.State = kube.SuspendedState
+	pj.Status.Description = "Jenkins job suspended."
+	// Clear any in-progress retry backoff: on resume, syncNonPendingJob
+	// starts a fresh Jenkins build and sets State back to Pending, and a
+	// stale NextRetryTime would then misroute that ProwJob back into
+	// syncPendingJob's backoff branch instead of the normal jb-lookup path.
+	pj.Status.NextRetryTime = time.Time{}
+	pj.Status.RetryCount = 0
+	if prevState != pj.Status.State {
+		c.log.WithFields(pjutil.ProwJobFields(&pj)).
+			WithField("from", prevState).
+			WithField("to", pj.Status.State).Info("Transitioning states.")
+	}
 	_, err := c.kc.ReplaceProwJob(pj.Metadata.Name, pj)
 	return err
 }
 
+// retryPendingJob starts a fresh Jenkins build for a ProwJob whose backoff
+// period has elapsed. It reports and persists the result itself, since it
+// is invoked before the usual jb-lookup path in syncPendingJob runs.
+func (c *Controller) retryPendingJob(pj kube.ProwJob, reports chan<- kube.ProwJob, prevState kube.ProwJobState) error {
+	master := masterFor(&pj)
+	if !c.masterHealthy(master) {
+		// Don't burn a retry attempt on a master we already know is down;
+		// just push the backoff out and try again next sync.
+		pj.Status.NextRetryTime = time.Now().Add(defaultRetryBase)
+		c.incrementNumPendingJobs(pj.Spec.Job, master)
+		reports <- pj
+		_, err := c.kc.ReplaceProwJob(pj.Metadata.Name, pj)
+		return err
+	}
+
+	pj.Status.NextRetryTime = time.Time{}
+	jc, jcErr := c.jenkinsClientFor(&pj)
+	if jcErr == nil {
+		jcErr = jc.Build(&pj)
+	}
+	if jcErr != nil {
+		c.log.WithError(jcErr).WithFields(pjutil.ProwJobFields(&pj)).Warn("Cannot start Jenkins retry build")
+		if c.retriesExhausted(&pj) {
+			pj.Status.CompletionTime = time.Now()
+			pj.Status.State = kube.ErrorState
+			pj.Status.URL = testInfra
+			pj.Status.Description = "Error starting Jenkins job."
+		} else {
+			c.stageRetry(&pj)
+		}
+	} else {
+		next, err := state.New(state.RetryingProwJobState).Execute(state.Retry, state.Context{})
+		if err != nil {
+			return err
+		}
+		pj.Status.State = next.Name()
+		pj.Status.Description = "Jenkins job enqueued."
+		c.incrementNumPendingJobs(pj.Spec.Job, master)
+	}
+	reports <- pj
+	if prevState != pj.Status.State {
+		c.log.WithFields(pjutil.ProwJobFields(&pj)).
+			WithField("from", prevState).
+			WithField("to", pj.Status.State).Info("Transitioning states.")
+	}
+	_, err := c.kc.ReplaceProwJob(pj.Metadata.Name, pj)
+	return err
+}
+
+// retriesExhausted reports whether a ProwJob has used up its retry budget,
+// or has none configured.
+func (c *Controller) retriesExhausted(pj *kube.ProwJob) bool {
+	return pj.Spec.MaxRetry <= 0 || pj.Status.RetryCount >= pj.Spec.MaxRetry
+}
+
+// stageRetry moves pj into state.RetryingProwJobState (which, since it has
+// no first-class kube.ProwJobState of its own, means leaving Status.State as
+// Pending and stamping NextRetryTime) with an exponential backoff delay
+// (base * 2^retryCount, capped at RetryPolicy.MaxBackoff), so that it is
+// picked up again by syncPendingJob once the delay has elapsed instead of
+// being reported as a terminal failure.
+func (c *Controller) stageRetry(pj *kube.ProwJob) {
+	policy := c.ca.Config().JenkinsOperator.RetryPolicy
+	base := policy.Base
+	if base <= 0 {
+		base = defaultRetryBase
+	}
+	max := policy.MaxBackoff
+	if max <= 0 {
+		max = defaultRetryCap
+	}
+	delay := base * (1 << uint(pj.Status.RetryCount))
+	if delay > max {
+		delay = max
+	}
+	if policy.Jitter > 0 {
+		delay = time.Duration(float64(delay) * (1 - policy.Jitter*rand.Float64()))
+	}
+	pj.Status.RetryCount++
+	pj.Status.NextRetryTime = time.Now().Add(delay)
+	pj.Status.Description = fmt.Sprintf("Jenkins job failed, retrying in %s.", delay.Round(time.Second))
+}
+
 func (c *Controller) syncNonPendingJob(pj kube.ProwJob, reports chan<- kube.ProwJob, jbs map[string]JenkinsBuild) error {
 	if pj.Complete() {
 		return nil
@@ -379,18 +754,55 @@ func (c *Controller) syncNonPendingJob(pj kube.ProwJob, reports chan<- kube.Prow
 	// The rest are new prowjobs.
 	prevState := pj.Status.State
 
+	if pj.Spec.Suspend != nil && *pj.Spec.Suspend {
+		if pj.Status.State == kube.SuspendedState {
+			return nil
+		}
+		pj.Status.State = kube.SuspendedState
+		pj.Status.Description = "Jenkins job suspended."
+		// See suspendPendingJob: leftover retry backoff must not survive a
+		// suspend, or resuming would misroute the ProwJob back into
+		// syncPendingJob's NextRetryTime branch instead of starting fresh.
+		pj.Status.NextRetryTime = time.Time{}
+		pj.Status.RetryCount = 0
+		c.log.WithFields(pjutil.ProwJobFields(&pj)).
+			WithField("from", prevState).
+			WithField("to", pj.Status.State).Info("Transitioning states.")
+		_, err := c.kc.ReplaceProwJob(pj.Metadata.Name, pj)
+		return err
+	}
+
 	if _, jbExists := jbs[pj.Metadata.Name]; !jbExists {
+		master := masterFor(&pj)
+		if !c.masterHealthy(master) {
+			// Leave the ProwJob as-is (Triggered); it will be picked up
+			// again once the master's health check succeeds.
+			return nil
+		}
 		// Do not start more jobs than specified.
-		if !c.canExecuteConcurrently(&pj) {
+		if !c.canExecuteConcurrently(&pj, master) {
 			return nil
 		}
 		// Start the Jenkins job.
-		if err := c.jc.Build(&pj); err != nil {
+		jc, err := c.jenkinsClientFor(&pj)
+		if err == nil {
+			err = jc.Build(&pj)
+		}
+		if err != nil {
 			c.log.WithError(err).WithFields(pjutil.ProwJobFields(&pj)).Warn("Cannot start Jenkins build")
-			pj.Status.CompletionTime = time.Now()
-			pj.Status.State = kube.ErrorState
-			pj.Status.URL = testInfra
-			pj.Status.Description = "Error starting Jenkins job."
+			if c.retriesExhausted(&pj) {
+				pj.Status.CompletionTime = time.Now()
+				pj.Status.State = kube.ErrorState
+				pj.Status.URL = testInfra
+				pj.Status.Description = "Error starting Jenkins job."
+			} else {
+				// Still Pending from kube's perspective: this keeps the
+				// ProwJob routed to syncPendingJob (which honors
+				// NextRetryTime) instead of re-entering this branch and
+				// re-triggering a build on every subsequent Sync.
+				pj.Status.State = kube.PendingState
+				c.stageRetry(&pj)
+			}
 		} else {
 			pj.Status.State = kube.PendingState
 			pj.Status.Description = "Jenkins job enqueued."
@@ -0,0 +1,190 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jenkins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/kube"
+)
+
+type fakeConfigAgent struct {
+	c config.Config
+}
+
+func (f *fakeConfigAgent) Config() *config.Config {
+	return &f.c
+}
+
+func newTestController(ca configAgent) *Controller {
+	return &Controller{
+		ca:                  ca,
+		log:                 logrus.NewEntry(logrus.StandardLogger()),
+		pendingJobs:         make(map[string]int),
+		pendingJobsByMaster: make(map[string]map[string]int),
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	tests := []struct {
+		name string
+		pj   kube.ProwJob
+		want bool
+	}{
+		{
+			name: "no MaxRetry configured means no retries",
+			pj:   kube.ProwJob{Spec: kube.ProwJobSpec{MaxRetry: 0}},
+			want: true,
+		},
+		{
+			name: "retries left",
+			pj:   kube.ProwJob{Spec: kube.ProwJobSpec{MaxRetry: 3}, Status: kube.ProwJobStatus{RetryCount: 2}},
+			want: false,
+		},
+		{
+			name: "retries used up exactly",
+			pj:   kube.ProwJob{Spec: kube.ProwJobSpec{MaxRetry: 3}, Status: kube.ProwJobStatus{RetryCount: 3}},
+			want: true,
+		},
+		{
+			name: "retries over budget",
+			pj:   kube.ProwJob{Spec: kube.ProwJobSpec{MaxRetry: 3}, Status: kube.ProwJobStatus{RetryCount: 5}},
+			want: true,
+		},
+	}
+	c := newTestController(&fakeConfigAgent{})
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := c.retriesExhausted(&tc.pj); got != tc.want {
+				t.Errorf("retriesExhausted() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStageRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     config.RetryPolicy
+		retryCount int32
+		wantDelay  time.Duration
+	}{
+		{
+			name:       "defaults apply when RetryPolicy is unset",
+			retryCount: 0,
+			wantDelay:  defaultRetryBase,
+		},
+		{
+			name:       "delay doubles with each retry",
+			retryCount: 2,
+			wantDelay:  defaultRetryBase * 4,
+		},
+		{
+			name:       "custom base is honored",
+			policy:     config.RetryPolicy{Base: time.Second},
+			retryCount: 3,
+			wantDelay:  8 * time.Second,
+		},
+		{
+			name:       "delay is capped at MaxBackoff",
+			policy:     config.RetryPolicy{Base: time.Second, MaxBackoff: 5 * time.Second},
+			retryCount: 10,
+			wantDelay:  5 * time.Second,
+		},
+		{
+			name:       "delay is capped at the default cap when unset",
+			retryCount: 20,
+			wantDelay:  defaultRetryCap,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestController(&fakeConfigAgent{c: config.Config{JenkinsOperator: config.JenkinsOperator{RetryPolicy: tc.policy}}})
+			pj := &kube.ProwJob{Status: kube.ProwJobStatus{RetryCount: tc.retryCount}}
+			before := time.Now()
+			c.stageRetry(pj)
+
+			if pj.Status.RetryCount != tc.retryCount+1 {
+				t.Errorf("RetryCount = %d, want %d", pj.Status.RetryCount, tc.retryCount+1)
+			}
+			got := pj.Status.NextRetryTime.Sub(before)
+			// Allow a little slack for the time spent executing the test itself.
+			if got < tc.wantDelay-time.Second || got > tc.wantDelay+time.Second {
+				t.Errorf("NextRetryTime delay = %s, want ~%s", got, tc.wantDelay)
+			}
+			if pj.Status.Description == "" {
+				t.Error("stageRetry did not set a Description")
+			}
+		})
+	}
+}
+
+func TestCanExecuteConcurrently(t *testing.T) {
+	const master = "default"
+
+	t.Run("global MaxConcurrency is enforced", func(t *testing.T) {
+		c := newTestController(&fakeConfigAgent{c: config.Config{JenkinsOperator: config.JenkinsOperator{MaxConcurrency: 1}}})
+		first := &kube.ProwJob{Spec: kube.ProwJobSpec{Job: "j1"}}
+		second := &kube.ProwJob{Spec: kube.ProwJobSpec{Job: "j2"}}
+		if !c.canExecuteConcurrently(first, master) {
+			t.Fatal("expected the first job to be allowed to run")
+		}
+		if c.canExecuteConcurrently(second, master) {
+			t.Fatal("expected the second job to be blocked by global MaxConcurrency")
+		}
+	})
+
+	t.Run("MaxConcurrencyPerMaster is enforced independently per master", func(t *testing.T) {
+		c := newTestController(&fakeConfigAgent{c: config.Config{JenkinsOperator: config.JenkinsOperator{MaxConcurrencyPerMaster: 1}}})
+		onDefault := &kube.ProwJob{Spec: kube.ProwJobSpec{Job: "j1"}}
+		alsoOnDefault := &kube.ProwJob{Spec: kube.ProwJobSpec{Job: "j2"}}
+		onOther := &kube.ProwJob{Spec: kube.ProwJobSpec{Job: "j3"}}
+		if !c.canExecuteConcurrently(onDefault, "default") {
+			t.Fatal("expected the first job on master \"default\" to be allowed to run")
+		}
+		if c.canExecuteConcurrently(alsoOnDefault, "default") {
+			t.Fatal("expected the second job on master \"default\" to be blocked by MaxConcurrencyPerMaster")
+		}
+		if !c.canExecuteConcurrently(onOther, "other") {
+			t.Fatal("expected a job on a different master to be unaffected by \"default\"'s concurrency")
+		}
+	})
+
+	t.Run("per-job MaxConcurrency is enforced", func(t *testing.T) {
+		c := newTestController(&fakeConfigAgent{})
+		first := &kube.ProwJob{Spec: kube.ProwJobSpec{Job: "j1", MaxConcurrency: 1}}
+		second := &kube.ProwJob{Spec: kube.ProwJobSpec{Job: "j1", MaxConcurrency: 1}}
+		if !c.canExecuteConcurrently(first, master) {
+			t.Fatal("expected the first instance of j1 to be allowed to run")
+		}
+		if c.canExecuteConcurrently(second, master) {
+			t.Fatal("expected the second instance of j1 to be blocked by its own MaxConcurrency")
+		}
+	})
+
+	t.Run("no limits configured always allows the job", func(t *testing.T) {
+		c := newTestController(&fakeConfigAgent{})
+		pj := &kube.ProwJob{Spec: kube.ProwJobSpec{Job: "j1"}}
+		if !c.canExecuteConcurrently(pj, master) {
+			t.Fatal("expected the job to be allowed to run with no limits configured")
+		}
+	})
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package state formalizes part of the ProwJob lifecycle used by the
+// Jenkins controller as an explicit state machine, mirroring the design
+// used by the Volcano job controller: each state is a small struct that
+// only knows how to compute its own outgoing transitions, so individual
+// transitions can be reasoned about (and tested) in isolation instead of
+// living inside the syncPendingJob/syncNonPendingJob switches.
+//
+// States are pure: they hold no I/O. The Jenkins controller observes the
+// world (the ProwJob and its Jenkins build), builds a Context, derives the
+// Action that applies, dispatches it, and performs whatever side effects
+// (starting/aborting a Jenkins build, persisting the ProwJob, reporting to
+// GitHub) the transition implies. Currently syncPendingJob dispatches
+// SyncStatus, terminateDupes dispatches Abort, and retryPendingJob
+// dispatches Retry; the Suspend/Resume and new-job-triggered transitions
+// are still handled inline in the controller.
+package state
+
+import "k8s.io/test-infra/prow/kube"
+
+// Action is an event the controller observed that may drive a State to
+// transition to another State.
+type Action string
+
+const (
+	// SyncStatus carries the latest observed Jenkins build status for a
+	// ProwJob and is emitted on every reconcile.
+	SyncStatus Action = "sync-status"
+	// Abort is emitted when a ProwJob's Jenkins build should be cancelled
+	// because it was superseded by a newer commit (terminateDupes). Marking
+	// a ProwJob suspended does not dispatch Abort; the controller moves it
+	// to SuspendedState directly.
+	Abort Action = "abort"
+	// Retry is emitted when a failed build's backoff has elapsed and a
+	// fresh Jenkins build should be started for the same ProwJob.
+	Retry Action = "retry"
+)
+
+// Build is the subset of a Jenkins build's status a State needs in order to
+// compute its next transition.
+type Build struct {
+	// Exists is false when no Jenkins build has been observed yet for the
+	// ProwJob (e.g. it raced with the Jenkins agent, or was garbage
+	// collected on the Jenkins side).
+	Exists   bool
+	Enqueued bool
+	Running  bool
+	Success  bool
+	Failure  bool
+	Aborted  bool
+}
+
+// Context carries everything a State needs to decide its next transition.
+// It is rebuilt from the ProwJob and its Jenkins build on every reconcile;
+// States never mutate it or hold a reference to it.
+type Context struct {
+	Build Build
+
+	// RetryCount and MaxRetry gate whether a Failure/Aborted build should
+	// be retried instead of treated as terminal.
+	RetryCount int32
+	MaxRetry   int32
+	// RetryJenkinsAborts distinguishes Jenkins-side aborts (master
+	// restarts, node loss) from operator-initiated ones (Suspend,
+	// terminateDupes): only the former are retriable, and only when set.
+	RetryJenkinsAborts bool
+}
+
+// State is one node of the ProwJob lifecycle. Implementations hold no
+// state of their own beyond identifying which lifecycle stage they are.
+type State interface {
+	// Name is the kube.ProwJobState this State represents.
+	Name() kube.ProwJobState
+	// Execute computes the next State for the given action. It returns the
+	// receiver unchanged if the action does not apply.
+	Execute(action Action, ctx Context) (State, error)
+}
+
+// New returns the State corresponding to a ProwJob's current
+// Status.State, defaulting to TriggeredState for a ProwJob that has not
+// been synced yet (the zero kube.ProwJobState).
+func New(name kube.ProwJobState) State {
+	switch name {
+	case kube.PendingState:
+		return PendingState{}
+	case kube.SuccessState:
+		return SuccessState{}
+	case kube.FailureState:
+		return FailureState{}
+	case kube.AbortedState:
+		return AbortedState{}
+	case kube.ErrorState:
+		return ErrorState{}
+	case kube.SuspendedState:
+		return SuspendedState{}
+	case RetryingProwJobState:
+		return RetryingState{}
+	default:
+		return TriggeredState{}
+	}
+}
+
+// RetryingProwJobState is the name of RetryingState, on top of the states
+// kube.ProwJob already defines. The Jenkins controller never writes it to
+// Status.State: a ProwJob waiting out a retry backoff keeps Status.State
+// Pending, and a non-zero Status.NextRetryTime is what marks it as
+// retrying.
+const RetryingProwJobState kube.ProwJobState = "retrying"
@@ -0,0 +1,148 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import "k8s.io/test-infra/prow/kube"
+
+// TriggeredState is a brand new ProwJob that has not had a Jenkins build
+// started for it yet.
+type TriggeredState struct{}
+
+func (TriggeredState) Name() kube.ProwJobState { return kube.TriggeredState }
+
+func (s TriggeredState) Execute(action Action, ctx Context) (State, error) {
+	switch action {
+	case Abort:
+		// jc.Abort is a blocking call, so by the time the controller
+		// dispatches Abort the Jenkins side is already settled.
+		return AbortedState{}, nil
+	case SyncStatus:
+		if ctx.Build.Exists {
+			return PendingState{}, nil
+		}
+		return s, nil
+	default:
+		return s, nil
+	}
+}
+
+// PendingState is a ProwJob with a Jenkins build in flight (enqueued or
+// running).
+type PendingState struct{}
+
+func (PendingState) Name() kube.ProwJobState { return kube.PendingState }
+
+func (s PendingState) Execute(action Action, ctx Context) (State, error) {
+	switch action {
+	case Abort:
+		// jc.Abort is a blocking call, so by the time the controller
+		// dispatches Abort the Jenkins side is already settled.
+		return AbortedState{}, nil
+	case SyncStatus:
+		if !ctx.Build.Exists {
+			return ErrorState{}, nil
+		}
+		switch {
+		case ctx.Build.Enqueued, ctx.Build.Running:
+			return s, nil
+		case ctx.Build.Success:
+			return SuccessState{}, nil
+		case ctx.Build.Failure:
+			if ctx.RetryCount < ctx.MaxRetry {
+				return RetryingState{}, nil
+			}
+			return FailureState{}, nil
+		case ctx.Build.Aborted:
+			if ctx.RetryJenkinsAborts && ctx.RetryCount < ctx.MaxRetry {
+				return RetryingState{}, nil
+			}
+			return AbortedState{}, nil
+		}
+		return s, nil
+	default:
+		return s, nil
+	}
+}
+
+// RetryingState is a ProwJob backing off after a failed build, waiting for
+// Status.NextRetryTime before a fresh Jenkins build is started.
+type RetryingState struct{}
+
+func (RetryingState) Name() kube.ProwJobState { return RetryingProwJobState }
+
+func (s RetryingState) Execute(action Action, ctx Context) (State, error) {
+	switch action {
+	case Retry:
+		return PendingState{}, nil
+	case Abort:
+		return AbortedState{}, nil
+	default:
+		return s, nil
+	}
+}
+
+// SuspendedState is a ProwJob the operator asked to pause; it has no
+// in-flight Jenkins build. Resuming it is handled directly by
+// syncNonPendingJob's Spec.Suspend check falling through to the normal
+// new-job path once Suspend is cleared, rather than through this package.
+type SuspendedState struct{}
+
+func (SuspendedState) Name() kube.ProwJobState { return kube.SuspendedState }
+
+func (s SuspendedState) Execute(action Action, ctx Context) (State, error) {
+	return s, nil
+}
+
+// SuccessState is a ProwJob whose Jenkins build succeeded.
+type SuccessState struct{}
+
+func (SuccessState) Name() kube.ProwJobState { return kube.SuccessState }
+
+func (s SuccessState) Execute(action Action, ctx Context) (State, error) {
+	return s, nil
+}
+
+// FailureState is a ProwJob whose Jenkins build failed and whose retries
+// (if any) are exhausted.
+type FailureState struct{}
+
+func (FailureState) Name() kube.ProwJobState { return kube.FailureState }
+
+func (s FailureState) Execute(action Action, ctx Context) (State, error) {
+	return s, nil
+}
+
+// AbortedState is a ProwJob whose Jenkins build was cancelled, either by
+// terminateDupes or by Jenkins itself. Suspended ProwJobs go to
+// SuspendedState instead.
+type AbortedState struct{}
+
+func (AbortedState) Name() kube.ProwJobState { return kube.AbortedState }
+
+func (s AbortedState) Execute(action Action, ctx Context) (State, error) {
+	return s, nil
+}
+
+// ErrorState is a ProwJob that could not be reconciled against Jenkins at
+// all, e.g. its build could not be found or could not be started.
+type ErrorState struct{}
+
+func (ErrorState) Name() kube.ProwJobState { return kube.ErrorState }
+
+func (s ErrorState) Execute(action Action, ctx Context) (State, error) {
+	return s, nil
+}
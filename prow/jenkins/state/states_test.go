@@ -0,0 +1,176 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"testing"
+
+	"k8s.io/test-infra/prow/kube"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		in   kube.ProwJobState
+		want kube.ProwJobState
+	}{
+		{"pending", kube.PendingState, kube.PendingState},
+		{"success", kube.SuccessState, kube.SuccessState},
+		{"failure", kube.FailureState, kube.FailureState},
+		{"aborted", kube.AbortedState, kube.AbortedState},
+		{"error", kube.ErrorState, kube.ErrorState},
+		{"suspended", kube.SuspendedState, kube.SuspendedState},
+		{"retrying", RetryingProwJobState, RetryingProwJobState},
+		{"unknown defaults to triggered", kube.ProwJobState("bogus"), kube.TriggeredState},
+		{"zero value defaults to triggered", kube.ProwJobState(""), kube.TriggeredState},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := New(tc.in).Name(); got != tc.want {
+				t.Errorf("New(%q).Name() = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTriggeredStateExecute(t *testing.T) {
+	tests := []struct {
+		name   string
+		action Action
+		ctx    Context
+		want   kube.ProwJobState
+	}{
+		{"sync with no build yet stays triggered", SyncStatus, Context{}, kube.TriggeredState},
+		{"sync once a build exists advances to pending", SyncStatus, Context{Build: Build{Exists: true}}, kube.PendingState},
+		{"abort goes straight to aborted", Abort, Context{}, kube.AbortedState},
+		{"retry does not apply", Retry, Context{}, kube.TriggeredState},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := TriggeredState{}.Execute(tc.action, tc.ctx)
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if got := next.Name(); got != tc.want {
+				t.Errorf("Execute(%q) = %q, want %q", tc.action, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPendingStateExecute(t *testing.T) {
+	tests := []struct {
+		name   string
+		action Action
+		ctx    Context
+		want   kube.ProwJobState
+	}{
+		{"abort goes straight to aborted", Abort, Context{}, kube.AbortedState},
+		{"build disappeared is an error", SyncStatus, Context{}, kube.ErrorState},
+		{"enqueued stays pending", SyncStatus, Context{Build: Build{Exists: true, Enqueued: true}}, kube.PendingState},
+		{"running stays pending", SyncStatus, Context{Build: Build{Exists: true, Running: true}}, kube.PendingState},
+		{"success advances to success", SyncStatus, Context{Build: Build{Exists: true, Success: true}}, kube.SuccessState},
+		{
+			"failure with retries left advances to retrying",
+			SyncStatus,
+			Context{Build: Build{Exists: true, Failure: true}, RetryCount: 0, MaxRetry: 2},
+			RetryingProwJobState,
+		},
+		{
+			"failure with retries exhausted advances to failure",
+			SyncStatus,
+			Context{Build: Build{Exists: true, Failure: true}, RetryCount: 2, MaxRetry: 2},
+			kube.FailureState,
+		},
+		{
+			"jenkins abort without RetryJenkinsAborts is terminal",
+			SyncStatus,
+			Context{Build: Build{Exists: true, Aborted: true}, RetryCount: 0, MaxRetry: 2},
+			kube.AbortedState,
+		},
+		{
+			"jenkins abort with RetryJenkinsAborts and retries left advances to retrying",
+			SyncStatus,
+			Context{Build: Build{Exists: true, Aborted: true}, RetryCount: 0, MaxRetry: 2, RetryJenkinsAborts: true},
+			RetryingProwJobState,
+		},
+		{
+			"jenkins abort with RetryJenkinsAborts but retries exhausted is terminal",
+			SyncStatus,
+			Context{Build: Build{Exists: true, Aborted: true}, RetryCount: 2, MaxRetry: 2, RetryJenkinsAborts: true},
+			kube.AbortedState,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := PendingState{}.Execute(tc.action, tc.ctx)
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if got := next.Name(); got != tc.want {
+				t.Errorf("Execute(%q) = %q, want %q", tc.action, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryingStateExecute(t *testing.T) {
+	tests := []struct {
+		name   string
+		action Action
+		want   kube.ProwJobState
+	}{
+		{"retry advances to pending", Retry, kube.PendingState},
+		{"abort advances to aborted", Abort, kube.AbortedState},
+		{"sync-status does not apply", SyncStatus, RetryingProwJobState},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := RetryingState{}.Execute(tc.action, Context{})
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if got := next.Name(); got != tc.want {
+				t.Errorf("Execute(%q) = %q, want %q", tc.action, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestTerminalStatesExecute verifies that states with no outgoing
+// transitions always return themselves, regardless of the action.
+func TestTerminalStatesExecute(t *testing.T) {
+	terminal := []State{
+		SuspendedState{},
+		SuccessState{},
+		FailureState{},
+		AbortedState{},
+		ErrorState{},
+	}
+	actions := []Action{SyncStatus, Abort, Retry}
+	for _, s := range terminal {
+		for _, action := range actions {
+			next, err := s.Execute(action, Context{})
+			if err != nil {
+				t.Fatalf("%T.Execute(%q) returned error: %v", s, action, err)
+			}
+			if got := next.Name(); got != s.Name() {
+				t.Errorf("%T.Execute(%q) = %q, want %q (no-op)", s, action, got, s.Name())
+			}
+		}
+	}
+}